@@ -0,0 +1,41 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound               = errors.New("not found")
+	ErrAlreadyExists          = errors.New("already exists")
+	ErrInvalidLoginOrPassword = errors.New("invalid login or password")
+	ErrUsernameAlreadyTaken   = errors.New("username already taken")
+	ErrInvalidAccessToken     = errors.New("invalid access token")
+	ErrInvalidRefreshToken    = errors.New("invalid refresh token")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrDontHavePermission     = errors.New("don't have permission")
+	ErrInvalidReauthToken     = errors.New("invalid or already used reauth token")
+	ErrInvalidInvite          = errors.New("invalid or expired invite")
+	ErrNotCollaborator        = errors.New("user is not a collaborator on this schedule")
+	ErrInvalidInviteRole      = errors.New("cannot invite a collaborator as owner")
+)
+
+// ServiceError wraps an unexpected internal error with the operation that
+// produced it, so logs can tell where in the call chain it happened without
+// leaking internals to the caller.
+type ServiceError struct {
+	op  string
+	err error
+}
+
+func NewServiceError(op string, err error) error {
+	return &ServiceError{op: op, err: err}
+}
+
+func (e *ServiceError) Error() string {
+	return fmt.Sprintf("%s: %v", e.op, e.err)
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+	"uni-schedule-backend/internal/domain"
+	"uni-schedule-backend/internal/repository"
+)
+
+// Logger appends rows to the audit log. It's deliberately thin: callers
+// build the domain.AuditEntryCreate, Logger just stamps the time if missing
+// and persists it.
+type Logger struct {
+	repo repository.AuditRepository
+}
+
+func NewLogger(repo repository.AuditRepository) *Logger {
+	return &Logger{repo: repo}
+}
+
+func (l *Logger) Log(entry domain.AuditEntryCreate) error {
+	if entry.At.IsZero() {
+		entry.At = time.Now().UTC()
+	}
+	return l.repo.Create(entry)
+}
+
+func (l *Logger) List(filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	return l.repo.List(filter)
+}
+
+// Diff renders a before/after pair as the compact JSON stored on update
+// entries. It never fails the caller's mutation: a value that can't be
+// marshalled just produces a nil diff.
+func Diff(before, after any) json.RawMessage {
+	diff, err := json.Marshal(struct {
+		Before any `json:"before"`
+		After  any `json:"after"`
+	}{before, after})
+	if err != nil {
+		return nil
+	}
+	return diff
+}
@@ -0,0 +1,66 @@
+package authz
+
+import (
+	"errors"
+	"uni-schedule-backend/internal/apperror"
+	"uni-schedule-backend/internal/domain"
+	"uni-schedule-backend/internal/repository"
+)
+
+// Permission is something a caller might want to do to a schedule.
+type Permission string
+
+const (
+	PermViewClasses     Permission = "view_classes"
+	PermEditClasses     Permission = "edit_classes"
+	PermManageMembers   Permission = "manage_members"
+	PermManageOwnership Permission = "manage_ownership"
+)
+
+var rolePermissions = map[domain.CollaboratorRole]map[Permission]bool{
+	domain.CollaboratorRoleViewer: {
+		PermViewClasses: true,
+	},
+	domain.CollaboratorRoleEditor: {
+		PermViewClasses: true,
+		PermEditClasses: true,
+	},
+	domain.CollaboratorRoleOwner: {
+		PermViewClasses:     true,
+		PermEditClasses:     true,
+		PermManageMembers:   true,
+		PermManageOwnership: true,
+	},
+}
+
+// Authorizer answers "can this user do this to this schedule", consulting
+// both the schedule's owner (who always has every permission) and the
+// schedule_collaborators table for everyone else.
+type Authorizer struct {
+	scheduleRepo     repository.ScheduleRepository
+	collaboratorRepo repository.CollaboratorRepository
+}
+
+func NewAuthorizer(scheduleRepo repository.ScheduleRepository, collaboratorRepo repository.CollaboratorRepository) *Authorizer {
+	return &Authorizer{scheduleRepo: scheduleRepo, collaboratorRepo: collaboratorRepo}
+}
+
+func (a *Authorizer) Can(userID domain.ID, scheduleID uint64, perm Permission) (bool, error) {
+	schedule, err := a.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		return false, err
+	}
+	if schedule.UserID == userID {
+		return true, nil
+	}
+
+	collaborator, err := a.collaboratorRepo.GetByScheduleAndUser(scheduleID, userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return rolePermissions[collaborator.Role][perm], nil
+}
@@ -0,0 +1,115 @@
+package authz
+
+import (
+	"errors"
+	"testing"
+	"uni-schedule-backend/internal/apperror"
+	"uni-schedule-backend/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type stubScheduleRepo struct {
+	schedule domain.Schedule
+	err      error
+}
+
+func (s *stubScheduleRepo) GetByID(id uint64) (domain.Schedule, error) {
+	return s.schedule, s.err
+}
+
+func (s *stubScheduleRepo) UpdateOwner(scheduleID uint64, newOwnerID domain.ID) error {
+	return nil
+}
+
+// stubCollaboratorRepo implements repository.CollaboratorRepository over a
+// plain map, keyed by user, so each test only has to describe who has which
+// role.
+type stubCollaboratorRepo struct {
+	byUser map[domain.ID]domain.Collaborator
+}
+
+func (s *stubCollaboratorRepo) Add(scheduleID uint64, userID domain.ID, role domain.CollaboratorRole) error {
+	return nil
+}
+
+func (s *stubCollaboratorRepo) GetByScheduleAndUser(scheduleID uint64, userID domain.ID) (domain.Collaborator, error) {
+	c, ok := s.byUser[userID]
+	if !ok {
+		return domain.Collaborator{}, apperror.ErrNotFound
+	}
+	return c, nil
+}
+
+func (s *stubCollaboratorRepo) ListForSchedule(scheduleID uint64) ([]domain.Collaborator, error) {
+	return nil, nil
+}
+
+func (s *stubCollaboratorRepo) UpdateRole(scheduleID uint64, userID domain.ID, role domain.CollaboratorRole) error {
+	return nil
+}
+
+func (s *stubCollaboratorRepo) Remove(scheduleID uint64, userID domain.ID) error { return nil }
+
+func (s *stubCollaboratorRepo) CreateInvite(invite domain.InvitationCreate) error { return nil }
+
+func (s *stubCollaboratorRepo) GetInviteByToken(token uuid.UUID) (domain.Invitation, error) {
+	return domain.Invitation{}, nil
+}
+
+func (s *stubCollaboratorRepo) ConsumeInvite(token uuid.UUID) error { return nil }
+
+func TestAuthorizer_Can(t *testing.T) {
+	const scheduleID = uint64(10)
+	const ownerID = domain.ID(1)
+	const editorID = domain.ID(2)
+	const viewerID = domain.ID(3)
+	const strangerID = domain.ID(4)
+
+	tests := []struct {
+		name   string
+		userID domain.ID
+		perm   Permission
+		want   bool
+	}{
+		{"owner can edit", ownerID, PermEditClasses, true},
+		{"owner can manage members", ownerID, PermManageMembers, true},
+		{"editor can edit", editorID, PermEditClasses, true},
+		{"editor cannot manage members", editorID, PermManageMembers, false},
+		{"viewer can view", viewerID, PermViewClasses, true},
+		{"viewer cannot edit", viewerID, PermEditClasses, false},
+		{"stranger cannot view", strangerID, PermViewClasses, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleRepo := &stubScheduleRepo{schedule: domain.Schedule{ID: scheduleID, UserID: uint64(ownerID)}}
+			collaboratorRepo := &stubCollaboratorRepo{
+				byUser: map[domain.ID]domain.Collaborator{
+					editorID: {ScheduleID: scheduleID, UserID: editorID, Role: domain.CollaboratorRoleEditor},
+					viewerID: {ScheduleID: scheduleID, UserID: viewerID, Role: domain.CollaboratorRoleViewer},
+				},
+			}
+
+			a := NewAuthorizer(scheduleRepo, collaboratorRepo)
+			got, err := a.Can(tt.userID, scheduleID, tt.perm)
+			if err != nil {
+				t.Fatalf("Can returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Can(%v, %v) = %v, want %v", tt.userID, tt.perm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_Can_ScheduleLookupError(t *testing.T) {
+	scheduleRepo := &stubScheduleRepo{err: errors.New("boom")}
+	collaboratorRepo := &stubCollaboratorRepo{byUser: map[domain.ID]domain.Collaborator{}}
+
+	a := NewAuthorizer(scheduleRepo, collaboratorRepo)
+	_, err := a.Can(1, 1, PermViewClasses)
+	if err == nil {
+		t.Fatal("expected an error when the schedule lookup fails")
+	}
+}
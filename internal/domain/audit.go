@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditTargetType string
+
+const (
+	AuditTargetClass    AuditTargetType = "class"
+	AuditTargetSchedule AuditTargetType = "schedule"
+	AuditTargetUser     AuditTargetType = "user"
+)
+
+type AuditAction string
+
+const (
+	AuditActionLoginSuccess     AuditAction = "login_success"
+	AuditActionLoginFailure     AuditAction = "login_failure"
+	AuditActionRegister         AuditAction = "register"
+	AuditActionRefresh          AuditAction = "refresh"
+	AuditActionRefreshReuse     AuditAction = "refresh_reuse_detected"
+	AuditActionClassCreate      AuditAction = "class_create"
+	AuditActionClassUpdate      AuditAction = "class_update"
+	AuditActionClassDelete      AuditAction = "class_delete"
+	AuditActionPermissionDenied AuditAction = "permission_denied"
+)
+
+// AuditEntry is one append-only row of the audit log: who did what to what,
+// and - for updates - a compact diff of what changed.
+type AuditEntry struct {
+	ID          uint64
+	ActorUserID ID
+	TargetType  AuditTargetType
+	TargetID    uint64
+	Action      AuditAction
+	IP          string
+	UserAgent   string
+	At          time.Time
+	Diff        json.RawMessage
+}
+
+type AuditEntryCreate struct {
+	ActorUserID ID
+	TargetType  AuditTargetType
+	TargetID    uint64
+	Action      AuditAction
+	IP          string
+	UserAgent   string
+	At          time.Time
+	Diff        json.RawMessage
+}
+
+// AuditFilter narrows a GET /admin/audit or GET /me/audit listing. A nil
+// field means "don't filter on this".
+type AuditFilter struct {
+	ActorUserID *ID
+	TargetType  *AuditTargetType
+	TargetID    *uint64
+	From        *time.Time
+	To          *time.Time
+}
@@ -0,0 +1,83 @@
+package domain
+
+import "time"
+
+// WeekParity restricts a class to every week, or only odd/even ISO week
+// numbers of the year - a common scheduling pattern for biweekly seminars.
+type WeekParity int
+
+const (
+	WeekParityEvery WeekParity = iota
+	WeekParityOdd
+	WeekParityEven
+)
+
+type Class struct {
+	ID         uint64
+	ScheduleID uint64
+	Subject    string
+	Teacher    string
+	Room       string
+	Weekday    time.Weekday
+	WeekParity WeekParity
+	StartTime  string
+	EndTime    string
+
+	// SemesterStart/SemesterEnd bound the recurrence (RRULE DTSTART/UNTIL).
+	SemesterStart time.Time
+	SemesterEnd   time.Time
+	// ExceptionDates are dates this class is cancelled (RRULE EXDATE).
+	ExceptionDates []time.Time
+}
+
+// ClassOccurrence is one concrete, dated meeting of a Class, produced by
+// expanding its recurrence rule over a date range.
+type ClassOccurrence struct {
+	ClassID uint64
+	Subject string
+	Teacher string
+	Room    string
+	Start   time.Time
+	End     time.Time
+}
+
+// ClassView is the read-optimized projection returned to clients listing a
+// schedule - it drops ScheduleID since it's implied by the request.
+type ClassView struct {
+	ID        uint64
+	Subject   string
+	Teacher   string
+	Room      string
+	Weekday   time.Weekday
+	StartTime string
+	EndTime   string
+}
+
+type CreateClassDTO struct {
+	ScheduleID uint64
+	Subject    string
+	Teacher    string
+	Room       string
+	Weekday    time.Weekday
+	WeekParity WeekParity
+	StartTime  string
+	EndTime    string
+
+	SemesterStart  time.Time
+	SemesterEnd    time.Time
+	ExceptionDates []time.Time
+}
+
+type UpdateClassDTO struct {
+	Subject   *string
+	Teacher   *string
+	Room      *string
+	Weekday   *time.Weekday
+	StartTime *string
+	EndTime   *string
+
+	WeekParity     *WeekParity
+	SemesterStart  *time.Time
+	SemesterEnd    *time.Time
+	ExceptionDates *[]time.Time
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CollaboratorRole string
+
+const (
+	CollaboratorRoleViewer CollaboratorRole = "viewer"
+	CollaboratorRoleEditor CollaboratorRole = "editor"
+	CollaboratorRoleOwner  CollaboratorRole = "owner"
+)
+
+type Collaborator struct {
+	ScheduleID uint64
+	UserID     ID
+	Role       CollaboratorRole
+	AddedAt    time.Time
+}
+
+// Invitation is a single-use, expiring token that lets someone who isn't a
+// collaborator yet join a schedule at a given role once they redeem it.
+type Invitation struct {
+	Token      uuid.UUID
+	ScheduleID uint64
+	Role       CollaboratorRole
+	ExpiresAt  time.Time
+	ConsumedAt *time.Time
+}
+
+type InvitationCreate struct {
+	Token      uuid.UUID
+	ScheduleID uint64
+	Role       CollaboratorRole
+	ExpiresAt  time.Time
+}
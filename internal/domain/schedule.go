@@ -0,0 +1,7 @@
+package domain
+
+type Schedule struct {
+	ID     uint64
+	UserID uint64
+	Name   string
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestMeta carries request-time context - who/where a mutation came from
+// - that we persist alongside sessions and audit log rows. It never
+// influences auth or authorization decisions.
+type RequestMeta struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is one row of a user's refresh-token history: a single device/
+// login keyed by SessionID. Refresh tokens rotate on every use, so a Session
+// row never gets a new token: rotation revokes it (RevokedAt) and sets
+// ReplacedBySession to point at its successor.
+type Session struct {
+	UserID            ID
+	SessionID         uuid.UUID
+	TokenHash         string
+	IssuedAt          time.Time
+	ExpiresAt         time.Time
+	RevokedAt         *time.Time
+	ReplacedBySession *uuid.UUID
+	UserAgent         string
+	IP                string
+}
+
+type SessionCreate struct {
+	UserID    ID
+	SessionID uuid.UUID
+	TokenHash string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	UserAgent string
+	IP        string
+}
@@ -0,0 +1,13 @@
+package domain
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+func NewTokenPair(accessToken, refreshToken string) TokenPair {
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}
+}
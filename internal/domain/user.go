@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// ID is the primary key type shared by all persisted entities.
+type ID = uint64
+
+type Role string
+
+const (
+	RoleStudent Role = "student"
+	RoleAdmin   Role = "admin"
+)
+
+type User struct {
+	ID           ID
+	Username     string
+	PasswordHash string
+	Role         Role
+	CreatedAt    time.Time
+}
+
+type UserCreate struct {
+	Username     string
+	PasswordHash string
+	Role         Role
+	CreatedAt    time.Time
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"uni-schedule-backend/internal/apperror"
+	"uni-schedule-backend/internal/domain"
+)
+
+const reauthHeader = "X-Reauth-Token"
+
+// Reauthenticator is satisfied by *auth.AuthService; it's declared here,
+// not imported from the auth package, so this package stays free to be used
+// by any handler without pulling in the rest of AuthService's dependencies.
+type Reauthenticator interface {
+	VerifyReauth(userID domain.ID, reauthToken string) error
+}
+
+type contextKey int
+
+const userIDContextKey contextKey = iota
+
+// ContextWithUserID attaches the authenticated caller's ID to the request
+// context. The regular access-token middleware upstream is expected to call
+// this once it has verified the access token, so every handler below it -
+// RequireReauth included - can read the caller back out per-request instead
+// of having it baked in at route-registration time.
+func ContextWithUserID(ctx context.Context, userID domain.ID) context.Context {
+	return context.WithValue(ctx, userIDContextKey, userID)
+}
+
+// UserIDFromContext returns the authenticated caller's ID stashed by the
+// access-token middleware, and false if none was set.
+func UserIDFromContext(ctx context.Context) (domain.ID, bool) {
+	userID, ok := ctx.Value(userIDContextKey).(domain.ID)
+	return userID, ok
+}
+
+// RequireReauth guards a handler for a sensitive operation (password
+// change, account deletion, schedule ownership transfer) behind a fresh
+// step-up token. It's registered once at route setup and reads the
+// already-authenticated caller out of the request context on every call,
+// rather than closing over a single userID at construction time.
+//
+// This trimmed tree has no router/handler package yet, so nothing actually
+// registers RequireReauth in front of password change, account deletion, or
+// a schedule-wide class delete - ScheduleService.TransferOwnership and
+// ScheduleService.RemoveCollaborator are the only other callers of
+// VerifyReauth so far. Wiring those routes up is out of scope
+// here and left for when the handler layer exists.
+func RequireReauth(auth Reauthenticator, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			writeError(w, apperror.ErrInvalidAccessToken)
+			return
+		}
+
+		reauthToken := r.Header.Get(reauthHeader)
+		if reauthToken == "" {
+			writeError(w, apperror.ErrInvalidReauthToken)
+			return
+		}
+
+		if err := auth.VerifyReauth(userID, reauthToken); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusForbidden)
+}
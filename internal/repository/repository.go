@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"time"
+	"uni-schedule-backend/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type UserRepository interface {
+	Create(user domain.UserCreate) (domain.ID, error)
+	GetByID(id domain.ID) (domain.User, error)
+	GetByUsername(username string) (domain.User, error)
+}
+
+// TokenRepository persists one row per session, keyed by (user_id,
+// session_id), rather than a single refresh token per user - this is what
+// lets a user be logged in on more than one device at a time and lets a
+// reused, already-rotated refresh token be detected.
+type TokenRepository interface {
+	Create(session domain.SessionCreate) error
+	GetBySessionID(userID domain.ID, sessionID uuid.UUID) (domain.Session, error)
+	Revoke(userID domain.ID, sessionID uuid.UUID, replacedBy *uuid.UUID) error
+	RevokeAllForUser(userID domain.ID) error
+	ListActiveForUser(userID domain.ID) ([]domain.Session, error)
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+// ReauthRepository tracks consumed step-up ("reauth") token jtis so each one
+// can be redeemed at most once, even though the JWT signature alone would
+// still verify after first use.
+type ReauthRepository interface {
+	// Consume records jti as used. It returns apperror.ErrAlreadyExists if
+	// the jti was already consumed.
+	Consume(jti string, userID domain.ID, expiresAt time.Time) error
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+type ClassRepository interface {
+	CreateOrSplit(class domain.CreateClassDTO) (uint64, error)
+	GetByID(id uint64) (domain.Class, error)
+	GetAllViews(scheduleID uint64) ([]domain.ClassView, int, error)
+	GetAllByScheduleID(scheduleID uint64) ([]domain.Class, error)
+	UpdateOrSwitch(id uint64, scheduleID uint64, update domain.UpdateClassDTO) error
+	Delete(id uint64) error
+}
+
+type ScheduleRepository interface {
+	GetByID(id uint64) (domain.Schedule, error)
+	UpdateOwner(scheduleID uint64, newOwnerID domain.ID) error
+}
+
+// CollaboratorRepository backs co-editing of a schedule: who has access
+// beyond its owner, at what role, and the invitation tokens used to grant
+// that access to someone who isn't a collaborator yet.
+type CollaboratorRepository interface {
+	Add(scheduleID uint64, userID domain.ID, role domain.CollaboratorRole) error
+	GetByScheduleAndUser(scheduleID uint64, userID domain.ID) (domain.Collaborator, error)
+	ListForSchedule(scheduleID uint64) ([]domain.Collaborator, error)
+	UpdateRole(scheduleID uint64, userID domain.ID, role domain.CollaboratorRole) error
+	Remove(scheduleID uint64, userID domain.ID) error
+
+	CreateInvite(invite domain.InvitationCreate) error
+	GetInviteByToken(token uuid.UUID) (domain.Invitation, error)
+	ConsumeInvite(token uuid.UUID) error
+}
+
+// AuditRepository is append-only: rows are never updated or deleted through
+// this interface, only created and listed.
+type AuditRepository interface {
+	Create(entry domain.AuditEntryCreate) error
+	List(filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
@@ -1,69 +1,112 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 	"uni-schedule-backend/internal/apperror"
+	"uni-schedule-backend/internal/audit"
 	"uni-schedule-backend/internal/domain"
 	"uni-schedule-backend/internal/repository"
 	"uni-schedule-backend/pkg/hash"
+
+	"github.com/google/uuid"
 )
 
+const refreshTokenTTL = 30 * 24 * time.Hour
+
 type JWTManager interface {
 	ParseAccessToken(token string) (domain.ID, error)
-	ParseRefreshToken(token string) (domain.ID, error)
-	GenerateAccessToken(userID domain.ID) (string, error)
-	GenerateRefreshToken(userID domain.ID) (string, error)
+	ParseRefreshToken(token string) (domain.ID, uuid.UUID, error)
+	GenerateAccessToken(userID domain.ID, sessionID uuid.UUID) (string, error)
+	GenerateRefreshToken(userID domain.ID, sessionID uuid.UUID) (string, error)
+	// GenerateReauthToken/ParseReauthToken handle the "reauth" claim purpose
+	// used for step-up tokens - see reauth.go. ParseReauthToken also returns
+	// the token's jti and expiry so the caller can enforce single use.
+	GenerateReauthToken(userID domain.ID) (string, error)
+	ParseReauthToken(token string) (userID domain.ID, jti string, expiresAt time.Time, err error)
+}
+
+// AuditLogger is satisfied by *audit.Logger. A logging failure is swallowed
+// (see logAudit) rather than turned into a service error - an unaudited
+// login must still succeed.
+type AuditLogger interface {
+	Log(entry domain.AuditEntryCreate) error
 }
 
 type AuthService struct {
 	passwordSalt string
 	userRepo     repository.UserRepository
 	tokenRepo    repository.TokenRepository
+	reauthRepo   repository.ReauthRepository
 	jwtManager   JWTManager
+	audit        AuditLogger
 }
 
-func NewAuthService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, jwtManager JWTManager, passwordSalt string) *AuthService {
+func NewAuthService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, reauthRepo repository.ReauthRepository, jwtManager JWTManager, audit AuditLogger, passwordSalt string) *AuthService {
 	return &AuthService{
 		userRepo:     userRepo,
 		tokenRepo:    tokenRepo,
+		reauthRepo:   reauthRepo,
 		jwtManager:   jwtManager,
+		audit:        audit,
 		passwordSalt: passwordSalt,
 	}
 }
 
-func (s *AuthService) Login(username, password string) (domain.TokenPair, error) {
+// unknownLoginActorID is the ActorUserID stamped on a login-failure row for
+// a username that doesn't exist, so the attempt still shows up in the audit
+// log instead of vanishing - that's the case an attacker enumerating or
+// credential-stuffing usernames actually produces.
+const unknownLoginActorID = domain.ID(0)
+
+// logAudit records an audit entry best-effort. Failing to write an audit
+// row must never fail the operation it's describing.
+func (s *AuthService) logAudit(actorUserID domain.ID, action domain.AuditAction, meta domain.RequestMeta) {
+	s.logAuditDiff(actorUserID, action, meta, nil)
+}
+
+func (s *AuthService) logAuditDiff(actorUserID domain.ID, action domain.AuditAction, meta domain.RequestMeta, diff json.RawMessage) {
+	_ = s.audit.Log(domain.AuditEntryCreate{
+		ActorUserID: actorUserID,
+		TargetType:  domain.AuditTargetUser,
+		TargetID:    actorUserID,
+		Action:      action,
+		IP:          meta.IP,
+		UserAgent:   meta.UserAgent,
+		Diff:        diff,
+	})
+}
+
+func (s *AuthService) Login(username, password string, meta domain.RequestMeta) (domain.TokenPair, error) {
 	user, err := s.userRepo.GetByUsername(username)
 	if err != nil {
 		if errors.Is(err, apperror.ErrNotFound) {
+			s.logAuditDiff(unknownLoginActorID, domain.AuditActionLoginFailure, meta, audit.Diff(nil, map[string]string{"attempted_username": username}))
 			return domain.TokenPair{}, apperror.ErrInvalidLoginOrPassword
 		}
 		return domain.TokenPair{}, err
 	}
 
 	if !hash.VerifyPassword(password, user.PasswordHash, s.passwordSalt) {
+		s.logAudit(user.ID, domain.AuditActionLoginFailure, meta)
 		return domain.TokenPair{}, apperror.ErrInvalidLoginOrPassword
 	}
 
-	tokenPair, err := s.generateTokenPair(user.ID)
+	tokenPair, err := s.startSession(user.ID, meta)
 	if err != nil {
 		return domain.TokenPair{}, apperror.NewServiceError("AuthService.Login:", err)
 	}
 
-	err = s.tokenRepo.CreateOrUpdate(domain.RefreshToken{
-		UserID:       user.ID,
-		RefreshToken: tokenPair.RefreshToken,
-		UpdatedAt:    time.Now().UTC(),
-	})
-	if err != nil {
-		return domain.TokenPair{}, apperror.NewServiceError("AuthService.Login: create or update token", err)
-	}
+	s.logAudit(user.ID, domain.AuditActionLoginSuccess, meta)
 
 	return tokenPair, nil
 }
 
-func (s *AuthService) Register(username, password string) (domain.TokenPair, error) {
+func (s *AuthService) Register(username, password string, meta domain.RequestMeta) (domain.TokenPair, error) {
 	passwordHash, err := hash.HashPassword(password, s.passwordSalt)
 	if err != nil {
 		return domain.TokenPair{}, apperror.NewServiceError("AuthService.Register: hashing password", err)
@@ -82,56 +125,102 @@ func (s *AuthService) Register(username, password string) (domain.TokenPair, err
 		return domain.TokenPair{}, apperror.NewServiceError("AuthService.Register: create user", err)
 	}
 
-	tokenPair, err := s.generateTokenPair(createdID)
+	tokenPair, err := s.startSession(createdID, meta)
 	if err != nil {
 		return domain.TokenPair{}, apperror.NewServiceError("AuthService.Register:", err)
 	}
 
-	err = s.tokenRepo.CreateOrUpdate(domain.RefreshToken{
-		UserID:       createdID,
-		RefreshToken: tokenPair.RefreshToken,
-		UpdatedAt:    time.Now().UTC(),
-	})
-	if err != nil {
-		return domain.TokenPair{}, apperror.NewServiceError("AuthService.Register: create or update token", err)
-	}
+	s.logAudit(createdID, domain.AuditActionRegister, meta)
 
 	return tokenPair, nil
 }
 
-func (s *AuthService) RefreshToken(refreshToken string) (domain.TokenPair, error) {
-	userID, err := s.jwtManager.ParseRefreshToken(refreshToken)
+// RefreshToken rotates the session named by the claims in refreshToken: the
+// presented token must still match what's stored for that session, the old
+// row is revoked, and a new session row takes its place. A refresh token
+// that names an already-revoked session is a replay of a token that was
+// already exchanged - either this token was stolen, or the legitimate owner
+// exchanged it and the thief is racing them, so every session for the user
+// is torn down rather than just refusing the one request.
+func (s *AuthService) RefreshToken(refreshToken string, meta domain.RequestMeta) (domain.TokenPair, error) {
+	userID, sessionID, err := s.jwtManager.ParseRefreshToken(refreshToken)
 	if err != nil {
 		return domain.TokenPair{}, apperror.ErrInvalidRefreshToken
 	}
 
-	storedToken, err := s.tokenRepo.GetByUserID(userID)
+	session, err := s.tokenRepo.GetBySessionID(userID, sessionID)
 	if err != nil {
 		if errors.Is(err, apperror.ErrNotFound) {
-			return domain.TokenPair{}, apperror.ErrUserNotFound
+			return domain.TokenPair{}, apperror.ErrInvalidRefreshToken
 		}
-		return domain.TokenPair{}, apperror.NewServiceError("AuthService.RefreshToken: getting user by id", err)
+		return domain.TokenPair{}, apperror.NewServiceError("AuthService.RefreshToken: getting session", err)
+	}
+
+	if session.TokenHash != hashRefreshToken(refreshToken) {
+		return domain.TokenPair{}, apperror.ErrInvalidRefreshToken
 	}
 
-	if storedToken.RefreshToken != refreshToken {
+	if session.RevokedAt != nil {
+		if err := s.tokenRepo.RevokeAllForUser(userID); err != nil {
+			return domain.TokenPair{}, apperror.NewServiceError("AuthService.RefreshToken: revoking sessions after reuse", err)
+		}
+		s.logAudit(userID, domain.AuditActionRefreshReuse, meta)
 		return domain.TokenPair{}, apperror.ErrInvalidRefreshToken
 	}
 
-	tokenPair, err := s.generateTokenPair(userID)
+	newSessionID := uuid.New()
+	if err := s.tokenRepo.Revoke(userID, sessionID, &newSessionID); err != nil {
+		return domain.TokenPair{}, apperror.NewServiceError("AuthService.RefreshToken: revoking old session", err)
+	}
+
+	tokenPair, err := s.createSession(userID, newSessionID, meta)
 	if err != nil {
 		return domain.TokenPair{}, apperror.NewServiceError("AuthService.RefreshToken:", err)
 	}
 
-	err = s.tokenRepo.CreateOrUpdate(domain.RefreshToken{
-		UserID:       userID,
-		RefreshToken: tokenPair.RefreshToken,
-		UpdatedAt:    time.Now().UTC(),
-	})
+	s.logAudit(userID, domain.AuditActionRefresh, meta)
+
+	return tokenPair, nil
+}
+
+// Logout revokes a single session, e.g. "log out this device". Logging out
+// a session that's already gone is not an error - the end state is the same.
+func (s *AuthService) Logout(userID domain.ID, sessionID uuid.UUID) error {
+	if err := s.tokenRepo.Revoke(userID, sessionID, nil); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		return apperror.NewServiceError("AuthService.Logout:", err)
+	}
+	return nil
+}
+
+// LogoutAll revokes every active session for the user, e.g. "log me out
+// everywhere" after a password change or a suspected compromise.
+func (s *AuthService) LogoutAll(userID domain.ID) error {
+	if err := s.tokenRepo.RevokeAllForUser(userID); err != nil {
+		return apperror.NewServiceError("AuthService.LogoutAll:", err)
+	}
+	return nil
+}
+
+// ListSessions returns the user's active sessions for a "devices" view.
+func (s *AuthService) ListSessions(userID domain.ID) ([]domain.Session, error) {
+	sessions, err := s.tokenRepo.ListActiveForUser(userID)
 	if err != nil {
-		return domain.TokenPair{}, apperror.NewServiceError("AuthService.RefreshToken: create or update token", err)
+		return nil, apperror.NewServiceError("AuthService.ListSessions:", err)
 	}
+	return sessions, nil
+}
 
-	return tokenPair, nil
+// PurgeExpiredSessions deletes session rows past their ExpiresAt. It's meant
+// to be called on a schedule (or opportunistically on read) rather than on
+// every request.
+func (s *AuthService) PurgeExpiredSessions() error {
+	if _, err := s.tokenRepo.DeleteExpired(time.Now().UTC()); err != nil {
+		return apperror.NewServiceError("AuthService.PurgeExpiredSessions:", err)
+	}
+	return nil
 }
 
 func (s *AuthService) GetUserFromAccessToken(accessToken string) (domain.User, error) {
@@ -147,15 +236,38 @@ func (s *AuthService) GetUserFromAccessToken(accessToken string) (domain.User, e
 	return user, nil
 }
 
-func (s *AuthService) generateTokenPair(userID domain.ID) (domain.TokenPair, error) {
-	accessToken, err := s.jwtManager.GenerateAccessToken(userID)
+func (s *AuthService) startSession(userID domain.ID, meta domain.RequestMeta) (domain.TokenPair, error) {
+	return s.createSession(userID, uuid.New(), meta)
+}
+
+func (s *AuthService) createSession(userID domain.ID, sessionID uuid.UUID, meta domain.RequestMeta) (domain.TokenPair, error) {
+	accessToken, err := s.jwtManager.GenerateAccessToken(userID, sessionID)
 	if err != nil {
-		return domain.TokenPair{}, fmt.Errorf("generateTokenPair.GenerateAccessToken: %w", err)
+		return domain.TokenPair{}, fmt.Errorf("generate access token: %w", err)
 	}
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(userID)
+	refreshToken, err := s.jwtManager.GenerateRefreshToken(userID, sessionID)
 	if err != nil {
-		return domain.TokenPair{}, fmt.Errorf("generateTokenPair.GenerateRefreshToken: %w", err)
+		return domain.TokenPair{}, fmt.Errorf("generate refresh token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	err = s.tokenRepo.Create(domain.SessionCreate{
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenHash: hashRefreshToken(refreshToken),
+		IssuedAt:  now,
+		ExpiresAt: now.Add(refreshTokenTTL),
+		UserAgent: meta.UserAgent,
+		IP:        meta.IP,
+	})
+	if err != nil {
+		return domain.TokenPair{}, fmt.Errorf("create session: %w", err)
 	}
 
 	return domain.NewTokenPair(accessToken, refreshToken), nil
-}
\ No newline at end of file
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,301 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"uni-schedule-backend/internal/apperror"
+	"uni-schedule-backend/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// fakeJWTManager encodes userID/sessionID into the token string itself
+// instead of signing a real JWT, so tests can assert on rotation and reuse
+// behavior without depending on a real JWTManager implementation.
+type fakeJWTManager struct{}
+
+func (fakeJWTManager) ParseAccessToken(token string) (domain.ID, error) { return 0, nil }
+
+func (fakeJWTManager) ParseRefreshToken(token string) (domain.ID, uuid.UUID, error) {
+	parts := strings.Split(token, ":")
+	if len(parts) != 3 || parts[0] != "refresh" {
+		return 0, uuid.UUID{}, fmt.Errorf("malformed fake refresh token %q", token)
+	}
+	var userID domain.ID
+	if _, err := fmt.Sscanf(parts[1], "%d", &userID); err != nil {
+		return 0, uuid.UUID{}, err
+	}
+	sessionID, err := uuid.Parse(parts[2])
+	if err != nil {
+		return 0, uuid.UUID{}, err
+	}
+	return userID, sessionID, nil
+}
+
+func (fakeJWTManager) GenerateAccessToken(userID domain.ID, sessionID uuid.UUID) (string, error) {
+	return fmt.Sprintf("access:%d:%s", userID, sessionID), nil
+}
+
+func (fakeJWTManager) GenerateRefreshToken(userID domain.ID, sessionID uuid.UUID) (string, error) {
+	return fmt.Sprintf("refresh:%d:%s", userID, sessionID), nil
+}
+
+func (fakeJWTManager) GenerateReauthToken(userID domain.ID) (string, error) { return "", nil }
+
+func (fakeJWTManager) ParseReauthToken(token string) (domain.ID, string, time.Time, error) {
+	return 0, "", time.Time{}, nil
+}
+
+// fakeTokenRepo is an in-memory repository.TokenRepository keyed by
+// SessionID, good enough to exercise rotation and reuse detection.
+type fakeTokenRepo struct {
+	sessions         map[uuid.UUID]domain.Session
+	revokeAllForUser domain.ID
+	revokeAllCalled  bool
+}
+
+func newFakeTokenRepo() *fakeTokenRepo {
+	return &fakeTokenRepo{sessions: map[uuid.UUID]domain.Session{}}
+}
+
+func (r *fakeTokenRepo) Create(session domain.SessionCreate) error {
+	r.sessions[session.SessionID] = domain.Session{
+		UserID:    session.UserID,
+		SessionID: session.SessionID,
+		TokenHash: session.TokenHash,
+		IssuedAt:  session.IssuedAt,
+		ExpiresAt: session.ExpiresAt,
+		UserAgent: session.UserAgent,
+		IP:        session.IP,
+	}
+	return nil
+}
+
+func (r *fakeTokenRepo) GetBySessionID(userID domain.ID, sessionID uuid.UUID) (domain.Session, error) {
+	session, ok := r.sessions[sessionID]
+	if !ok || session.UserID != userID {
+		return domain.Session{}, apperror.ErrNotFound
+	}
+	return session, nil
+}
+
+func (r *fakeTokenRepo) Revoke(userID domain.ID, sessionID uuid.UUID, replacedBy *uuid.UUID) error {
+	session, ok := r.sessions[sessionID]
+	if !ok || session.UserID != userID {
+		return apperror.ErrNotFound
+	}
+	now := time.Now().UTC()
+	session.RevokedAt = &now
+	session.ReplacedBySession = replacedBy
+	r.sessions[sessionID] = session
+	return nil
+}
+
+func (r *fakeTokenRepo) RevokeAllForUser(userID domain.ID) error {
+	r.revokeAllCalled = true
+	r.revokeAllForUser = userID
+	now := time.Now().UTC()
+	for id, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			r.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (r *fakeTokenRepo) ListActiveForUser(userID domain.ID) ([]domain.Session, error) {
+	var out []domain.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			out = append(out, session)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeTokenRepo) DeleteExpired(before time.Time) (int64, error) { return 0, nil }
+
+type fakeUserRepo struct {
+	byUsername map[string]domain.User
+	byID       map[domain.ID]domain.User
+}
+
+func (r *fakeUserRepo) Create(user domain.UserCreate) (domain.ID, error) { return 0, nil }
+
+func (r *fakeUserRepo) GetByID(id domain.ID) (domain.User, error) {
+	user, ok := r.byID[id]
+	if !ok {
+		return domain.User{}, apperror.ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepo) GetByUsername(username string) (domain.User, error) {
+	user, ok := r.byUsername[username]
+	if !ok {
+		return domain.User{}, apperror.ErrNotFound
+	}
+	return user, nil
+}
+
+type fakeReauthRepo struct{}
+
+func (fakeReauthRepo) Consume(jti string, userID domain.ID, expiresAt time.Time) error { return nil }
+func (fakeReauthRepo) DeleteExpired(before time.Time) (int64, error)                   { return 0, nil }
+
+type fakeAuditLogger struct {
+	entries []domain.AuditEntryCreate
+}
+
+func (l *fakeAuditLogger) Log(entry domain.AuditEntryCreate) error {
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *fakeAuditLogger) actions() []domain.AuditAction {
+	actions := make([]domain.AuditAction, len(l.entries))
+	for i, e := range l.entries {
+		actions[i] = e.Action
+	}
+	return actions
+}
+
+func newTestAuthService(tokenRepo *fakeTokenRepo, userRepo *fakeUserRepo, audit *fakeAuditLogger) *AuthService {
+	return NewAuthService(userRepo, tokenRepo, fakeReauthRepo{}, fakeJWTManager{}, audit, "salt")
+}
+
+func TestAuthService_RefreshToken_RotatesSession(t *testing.T) {
+	tokenRepo := newFakeTokenRepo()
+	audit := &fakeAuditLogger{}
+	s := newTestAuthService(tokenRepo, &fakeUserRepo{}, audit)
+
+	pair, err := s.startSession(7, domain.RequestMeta{})
+	if err != nil {
+		t.Fatalf("startSession: %v", err)
+	}
+
+	refreshed, err := s.RefreshToken(pair.RefreshToken, domain.RequestMeta{})
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if refreshed.RefreshToken == pair.RefreshToken {
+		t.Fatal("RefreshToken returned the same refresh token instead of rotating it")
+	}
+
+	_, oldSessionID, _ := fakeJWTManager{}.ParseRefreshToken(pair.RefreshToken)
+	oldSession, err := tokenRepo.GetBySessionID(7, oldSessionID)
+	if err != nil {
+		t.Fatalf("old session vanished instead of being revoked-and-replaced: %v", err)
+	}
+	if oldSession.RevokedAt == nil {
+		t.Error("old session should be revoked after rotation")
+	}
+	if oldSession.ReplacedBySession == nil {
+		t.Error("old session should point at its replacement")
+	}
+
+	if tokenRepo.revokeAllCalled {
+		t.Error("a normal rotation must not revoke every session for the user")
+	}
+
+	wantActions := []domain.AuditAction{domain.AuditActionRefresh}
+	if got := audit.actions(); len(got) != 1 || got[0] != wantActions[0] {
+		t.Errorf("audit actions = %v, want %v", got, wantActions)
+	}
+}
+
+func TestAuthService_RefreshToken_ReuseRevokesAllSessions(t *testing.T) {
+	tokenRepo := newFakeTokenRepo()
+	audit := &fakeAuditLogger{}
+	s := newTestAuthService(tokenRepo, &fakeUserRepo{}, audit)
+
+	pair, err := s.startSession(7, domain.RequestMeta{})
+	if err != nil {
+		t.Fatalf("startSession: %v", err)
+	}
+
+	if _, err := s.RefreshToken(pair.RefreshToken, domain.RequestMeta{}); err != nil {
+		t.Fatalf("first RefreshToken: %v", err)
+	}
+
+	// Replaying the already-rotated refresh token is a reuse/theft signal.
+	_, err = s.RefreshToken(pair.RefreshToken, domain.RequestMeta{})
+	if !errors.Is(err, apperror.ErrInvalidRefreshToken) {
+		t.Fatalf("replayed refresh token returned %v, want ErrInvalidRefreshToken", err)
+	}
+
+	if !tokenRepo.revokeAllCalled || tokenRepo.revokeAllForUser != 7 {
+		t.Error("reuse of a rotated refresh token should revoke every session for that user")
+	}
+
+	found := false
+	for _, action := range audit.actions() {
+		if action == domain.AuditActionRefreshReuse {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a refresh_reuse_detected audit entry")
+	}
+}
+
+func TestAuthService_RefreshToken_HashMismatchRejected(t *testing.T) {
+	tokenRepo := newFakeTokenRepo()
+	s := newTestAuthService(tokenRepo, &fakeUserRepo{}, &fakeAuditLogger{})
+
+	pair, err := s.startSession(7, domain.RequestMeta{})
+	if err != nil {
+		t.Fatalf("startSession: %v", err)
+	}
+
+	_, sessionID, _ := fakeJWTManager{}.ParseRefreshToken(pair.RefreshToken)
+	session := tokenRepo.sessions[sessionID]
+	session.TokenHash = "tampered"
+	tokenRepo.sessions[sessionID] = session
+
+	_, err = s.RefreshToken(pair.RefreshToken, domain.RequestMeta{})
+	if !errors.Is(err, apperror.ErrInvalidRefreshToken) {
+		t.Fatalf("got %v, want ErrInvalidRefreshToken when the stored hash doesn't match", err)
+	}
+}
+
+func TestAuthService_Login_UnknownUsernameIsAudited(t *testing.T) {
+	audit := &fakeAuditLogger{}
+	s := newTestAuthService(newFakeTokenRepo(), &fakeUserRepo{byUsername: map[string]domain.User{}}, audit)
+
+	_, err := s.Login("ghost", "whatever", domain.RequestMeta{})
+	if !errors.Is(err, apperror.ErrInvalidLoginOrPassword) {
+		t.Fatalf("got %v, want ErrInvalidLoginOrPassword", err)
+	}
+
+	if len(audit.entries) != 1 || audit.entries[0].Action != domain.AuditActionLoginFailure {
+		t.Fatalf("expected a single login_failure entry, got %+v", audit.entries)
+	}
+	if !strings.Contains(string(audit.entries[0].Diff), "ghost") {
+		t.Errorf("login_failure entry should record the attempted username, got diff %q", audit.entries[0].Diff)
+	}
+}
+
+func TestAuthService_Login_WrongPasswordIsAudited(t *testing.T) {
+	audit := &fakeAuditLogger{}
+	userRepo := &fakeUserRepo{byUsername: map[string]domain.User{
+		"alice": {ID: 1, Username: "alice", PasswordHash: "does-not-match-anything"},
+	}}
+	s := newTestAuthService(newFakeTokenRepo(), userRepo, audit)
+
+	_, err := s.Login("alice", "wrong-password", domain.RequestMeta{})
+	if !errors.Is(err, apperror.ErrInvalidLoginOrPassword) {
+		t.Fatalf("got %v, want ErrInvalidLoginOrPassword", err)
+	}
+
+	if len(audit.entries) != 1 || audit.entries[0].Action != domain.AuditActionLoginFailure {
+		t.Fatalf("expected a single login_failure entry, got %+v", audit.entries)
+	}
+	if audit.entries[0].ActorUserID != 1 {
+		t.Errorf("login_failure entry should be tagged with the known user's ID, got %v", audit.entries[0].ActorUserID)
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"time"
+	"uni-schedule-backend/internal/apperror"
+	"uni-schedule-backend/internal/domain"
+	"uni-schedule-backend/pkg/hash"
+)
+
+// reauthTokenTTL is short: a reauth token only proves "this user typed their
+// password a moment ago", not a fresh login session. Nothing in this package
+// applies it: GenerateReauthToken takes no lifetime, so an issued token lives
+// as long as the JWTManager implementation makes it.
+const reauthTokenTTL = 5 * time.Minute
+
+// Reauthenticate checks the user's password again and, on success, mints a
+// short-lived single-use step-up token that guards sensitive operations
+// (password change, account deletion, ownership transfer) without forcing a
+// full re-login.
+func (s *AuthService) Reauthenticate(userID domain.ID, password string) (string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return "", apperror.ErrUserNotFound
+		}
+		return "", apperror.NewServiceError("AuthService.Reauthenticate: getting user", err)
+	}
+
+	if !hash.VerifyPassword(password, user.PasswordHash, s.passwordSalt) {
+		return "", apperror.ErrInvalidLoginOrPassword
+	}
+
+	reauthToken, err := s.jwtManager.GenerateReauthToken(userID)
+	if err != nil {
+		return "", apperror.NewServiceError("AuthService.Reauthenticate: generating reauth token", err)
+	}
+
+	return reauthToken, nil
+}
+
+// VerifyReauth is what RequireReauth calls: it checks that reauthToken is a
+// valid, unexpired step-up token issued to userID, and consumes it so it
+// can't be replayed for a second sensitive operation.
+func (s *AuthService) VerifyReauth(userID domain.ID, reauthToken string) error {
+	tokenUserID, jti, expiresAt, err := s.jwtManager.ParseReauthToken(reauthToken)
+	if err != nil {
+		return apperror.ErrInvalidReauthToken
+	}
+	if tokenUserID != userID {
+		return apperror.ErrInvalidReauthToken
+	}
+
+	if err := s.reauthRepo.Consume(jti, userID, expiresAt); err != nil {
+		if errors.Is(err, apperror.ErrAlreadyExists) {
+			return apperror.ErrInvalidReauthToken
+		}
+		return apperror.NewServiceError("AuthService.VerifyReauth: consuming reauth token", err)
+	}
+
+	return nil
+}
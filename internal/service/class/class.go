@@ -2,33 +2,75 @@ package class
 
 import (
 	"uni-schedule-backend/internal/apperror"
+	"uni-schedule-backend/internal/audit"
+	"uni-schedule-backend/internal/authz"
 	"uni-schedule-backend/internal/domain"
 	"uni-schedule-backend/internal/repository"
 )
 
+// Authorizer is satisfied by *authz.Authorizer. It's declared locally so
+// this package depends on an interface it owns, not the concrete type.
+type Authorizer interface {
+	Can(userID domain.ID, scheduleID uint64, perm authz.Permission) (bool, error)
+}
+
+// AuditLogger is satisfied by *audit.Logger.
+type AuditLogger interface {
+	Log(entry domain.AuditEntryCreate) error
+}
+
 type ClassService struct {
-	repo         repository.ClassRepository
-	scheduleRepo repository.ScheduleRepository
+	repo             repository.ClassRepository
+	authz            Authorizer
+	audit            AuditLogger
+	icsSigningSecret string
 }
 
-func NewClassService(repo repository.ClassRepository, scheduleRepo repository.ScheduleRepository) *ClassService {
-	return &ClassService{repo: repo, scheduleRepo: scheduleRepo}
+func NewClassService(repo repository.ClassRepository, authz Authorizer, audit AuditLogger, icsSigningSecret string) *ClassService {
+	return &ClassService{repo: repo, authz: authz, audit: audit, icsSigningSecret: icsSigningSecret}
 }
 
-func (s *ClassService) Create(class domain.CreateClassDTO) (uint64, error) {
+func (s *ClassService) Create(userID uint64, class domain.CreateClassDTO, meta domain.RequestMeta) (uint64, error) {
+	if err := s.requirePermission(userID, class.ScheduleID, meta); err != nil {
+		return 0, err
+	}
+
 	createdClassID, err := s.repo.CreateOrSplit(class)
 	if err != nil {
 		return 0, err
 	}
 
+	s.logAudit(userID, domain.AuditActionClassCreate, domain.AuditTargetClass, createdClassID, meta, nil)
+
 	return createdClassID, nil
 }
 
-func (s *ClassService) GetByID(id uint64) (domain.Class, error) {
-	return s.repo.GetByID(id)
+func (s *ClassService) GetByID(userID uint64, id uint64) (domain.Class, error) {
+	class, err := s.repo.GetByID(id)
+	if err != nil {
+		return domain.Class{}, err
+	}
+
+	can, err := s.authz.Can(userID, class.ScheduleID, authz.PermViewClasses)
+	if err != nil {
+		return domain.Class{}, apperror.NewServiceError("ClassService.GetByID: checking permission", err)
+	}
+	if !can {
+		return domain.Class{}, apperror.ErrDontHavePermission
+	}
+
+	return class, nil
 }
 
-func (s *ClassService) GetAll(scheduleID uint64) ([]domain.ClassView, error) {
+func (s *ClassService) GetAll(userID uint64, scheduleID uint64) ([]domain.ClassView, error) {
+	can, err := s.authz.Can(userID, scheduleID, authz.PermViewClasses)
+	if err != nil {
+		return nil, apperror.NewServiceError("ClassService.GetAll: checking permission", err)
+	}
+	if !can {
+		return nil, apperror.ErrDontHavePermission
+	}
+
 	classes, _, err := s.repo.GetAllViews(scheduleID)
 	if err != nil {
 		return classes, err
@@ -37,41 +79,72 @@ func (s *ClassService) GetAll(scheduleID uint64) ([]domain.ClassView, error) {
 	return classes, nil
 }
 
-func (s *ClassService) Update(userID uint64, id uint64, update domain.UpdateClassDTO) error {
-	class, err := s.repo.GetByID(id)
+func (s *ClassService) Update(userID uint64, id uint64, update domain.UpdateClassDTO, meta domain.RequestMeta) error {
+	before, err := s.repo.GetByID(id)
 	if err != nil {
 		return err
 	}
-	schedule, err := s.scheduleRepo.GetByID(class.ScheduleID)
-	if err != nil {
+	if err := s.requirePermission(userID, before.ScheduleID, meta); err != nil {
 		return err
 	}
-	if schedule.UserID != userID {
-		return apperror.ErrDontHavePermission
-	}
 
-	return s.repo.UpdateOrSwitch(id, class.ScheduleID, update)
-}
+	if err := s.repo.UpdateOrSwitch(id, before.ScheduleID, update); err != nil {
+		return err
+	}
 
-func (s *ClassService) Delete(userID uint64, id uint64) error {
-	if err := s.isScheduleOwner(userID, id); err != nil {
+	after, err := s.repo.GetByID(id)
+	if err != nil {
 		return err
 	}
-	return s.repo.Delete(id)
+	s.logAudit(userID, domain.AuditActionClassUpdate, domain.AuditTargetClass, id, meta, audit.Diff(before, after))
+
+	return nil
 }
 
-func (s *ClassService) isScheduleOwner(userID uint64, classID uint64) error {
-	entry, err := s.repo.GetByID(classID)
+func (s *ClassService) Delete(userID uint64, id uint64, meta domain.RequestMeta) error {
+	class, err := s.repo.GetByID(id)
 	if err != nil {
 		return err
 	}
-	schedule, err := s.scheduleRepo.GetByID(entry.ScheduleID)
-	if err != nil {
+	if err := s.requirePermission(userID, class.ScheduleID, meta); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(id); err != nil {
 		return err
 	}
-	if schedule.UserID != userID {
+
+	s.logAudit(userID, domain.AuditActionClassDelete, domain.AuditTargetClass, id, meta, nil)
+
+	return nil
+}
+
+func (s *ClassService) requirePermission(userID uint64, scheduleID uint64, meta domain.RequestMeta) error {
+	can, err := s.authz.Can(userID, scheduleID, authz.PermEditClasses)
+	if err != nil {
+		return apperror.NewServiceError("ClassService.requirePermission:", err)
+	}
+	if !can {
+		// scheduleID, not a class ID - the denied action was "edit something
+		// on this schedule", so the row must be tagged accordingly or it
+		// collides with real class IDs under target_type=class.
+		s.logAudit(userID, domain.AuditActionPermissionDenied, domain.AuditTargetSchedule, scheduleID, meta, nil)
 		return apperror.ErrDontHavePermission
 	}
 
 	return nil
 }
+
+// logAudit records an audit entry best-effort: a failure to write it must
+// never fail the class mutation it's describing.
+func (s *ClassService) logAudit(actorUserID uint64, action domain.AuditAction, targetType domain.AuditTargetType, targetID uint64, meta domain.RequestMeta, diff []byte) {
+	_ = s.audit.Log(domain.AuditEntryCreate{
+		ActorUserID: actorUserID,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		Action:      action,
+		IP:          meta.IP,
+		UserAgent:   meta.UserAgent,
+		Diff:        diff,
+	})
+}
@@ -0,0 +1,208 @@
+package class
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"uni-schedule-backend/internal/apperror"
+	"uni-schedule-backend/internal/domain"
+)
+
+const icsDateTimeLayout = "20060102T150405Z"
+
+var weekdayICS = map[time.Weekday]string{
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+	time.Sunday:    "SU",
+}
+
+// ExportICS renders every class on a schedule as an iCalendar feed: one
+// VEVENT per class with a weekly RRULE (INTERVAL=2 for classes that only
+// meet on odd/even weeks) and one EXDATE per cancellation, so calendar apps
+// can subscribe to a live timetable instead of importing a static snapshot.
+// from/to decide which classes are included (a class whose semester window
+// doesn't overlap it is skipped) but don't truncate the RRULE itself - a
+// subscribed calendar keeps following the schedule past the export window.
+func (s *ClassService) ExportICS(scheduleID uint64, from, to time.Time) ([]byte, error) {
+	classes, err := s.repo.GetAllByScheduleID(scheduleID)
+	if err != nil {
+		return nil, apperror.NewServiceError("ClassService.ExportICS: listing classes", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("BEGIN:VCALENDAR\r\n")
+	buf.WriteString("VERSION:2.0\r\n")
+	buf.WriteString("PRODID:-//uni-schedule//uni-schedule-backend//EN\r\n")
+
+	for _, c := range classes {
+		if c.SemesterEnd.Before(from) || c.SemesterStart.After(to) {
+			continue
+		}
+		writeVEvent(&buf, c)
+	}
+
+	buf.WriteString("END:VCALENDAR\r\n")
+	return buf.Bytes(), nil
+}
+
+func writeVEvent(buf *bytes.Buffer, c domain.Class) {
+	start, startErr := combineDateTime(firstMatchingDate(c), c.StartTime)
+	end, endErr := combineDateTime(firstMatchingDate(c), c.EndTime)
+	if startErr != nil || endErr != nil {
+		return
+	}
+
+	interval := 1
+	if c.WeekParity != domain.WeekParityEvery {
+		interval = 2
+	}
+
+	buf.WriteString("BEGIN:VEVENT\r\n")
+	fmt.Fprintf(buf, "UID:class-%d@uni-schedule\r\n", c.ID)
+	fmt.Fprintf(buf, "DTSTART:%s\r\n", start.UTC().Format(icsDateTimeLayout))
+	fmt.Fprintf(buf, "DTEND:%s\r\n", end.UTC().Format(icsDateTimeLayout))
+	fmt.Fprintf(buf, "RRULE:FREQ=WEEKLY;BYDAY=%s;INTERVAL=%d;UNTIL=%s\r\n",
+		weekdayICS[c.Weekday], interval, c.SemesterEnd.UTC().Format(icsDateTimeLayout))
+	for _, exc := range c.ExceptionDates {
+		excStart, err := combineDateTime(exc, c.StartTime)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(buf, "EXDATE:%s\r\n", excStart.UTC().Format(icsDateTimeLayout))
+	}
+	fmt.Fprintf(buf, "SUMMARY:%s\r\n", icsEscape(c.Subject))
+	fmt.Fprintf(buf, "LOCATION:%s\r\n", icsEscape(c.Room))
+	fmt.Fprintf(buf, "DESCRIPTION:%s\r\n", icsEscape(c.Teacher))
+	buf.WriteString("END:VEVENT\r\n")
+}
+
+// ExpandOccurrences materializes every class meeting between from and to
+// into concrete, dated occurrences - the same recurrence math that backs
+// ExportICS, reused so a future week/day view doesn't have to reimplement
+// RRULE-style expansion.
+func (s *ClassService) ExpandOccurrences(scheduleID uint64, from, to time.Time) ([]domain.ClassOccurrence, error) {
+	classes, err := s.repo.GetAllByScheduleID(scheduleID)
+	if err != nil {
+		return nil, apperror.NewServiceError("ClassService.ExpandOccurrences: listing classes", err)
+	}
+
+	var occurrences []domain.ClassOccurrence
+	for _, c := range classes {
+		occurrences = append(occurrences, expandClass(c, from, to)...)
+	}
+
+	return occurrences, nil
+}
+
+func expandClass(c domain.Class, from, to time.Time) []domain.ClassOccurrence {
+	rangeStart := c.SemesterStart
+	if from.After(rangeStart) {
+		rangeStart = from
+	}
+	rangeEnd := c.SemesterEnd
+	if to.Before(rangeEnd) {
+		rangeEnd = to
+	}
+
+	var occurrences []domain.ClassOccurrence
+	for date := rangeStart; !date.After(rangeEnd); date = date.AddDate(0, 0, 1) {
+		if date.Weekday() != c.Weekday || !matchesParity(c, date) || isException(c, date) {
+			continue
+		}
+
+		start, err := combineDateTime(date, c.StartTime)
+		if err != nil {
+			continue
+		}
+		end, err := combineDateTime(date, c.EndTime)
+		if err != nil {
+			continue
+		}
+
+		occurrences = append(occurrences, domain.ClassOccurrence{
+			ClassID: c.ID,
+			Subject: c.Subject,
+			Teacher: c.Teacher,
+			Room:    c.Room,
+			Start:   start,
+			End:     end,
+		})
+	}
+
+	return occurrences
+}
+
+// firstMatchingDate returns the first date on/after SemesterStart that
+// falls on c's weekday and parity - the anchor date RRULE's DTSTART needs.
+func firstMatchingDate(c domain.Class) time.Time {
+	date := c.SemesterStart
+	for i := 0; i < 14; i++ {
+		if date.Weekday() == c.Weekday && matchesParity(c, date) {
+			return date
+		}
+		date = date.AddDate(0, 0, 1)
+	}
+	return c.SemesterStart
+}
+
+func matchesParity(c domain.Class, date time.Time) bool {
+	if c.WeekParity == domain.WeekParityEvery {
+		return true
+	}
+	_, week := date.ISOWeek()
+	if c.WeekParity == domain.WeekParityOdd {
+		return week%2 == 1
+	}
+	return week%2 == 0
+}
+
+func isException(c domain.Class, date time.Time) bool {
+	for _, exc := range c.ExceptionDates {
+		if exc.Year() == date.Year() && exc.YearDay() == date.YearDay() {
+			return true
+		}
+	}
+	return false
+}
+
+func combineDateTime(date time.Time, clock string) (time.Time, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing class time %q: %w", clock, err)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, date.Location()), nil
+}
+
+func icsEscape(s string) string {
+	r := strings.NewReplacer(",", "\\,", ";", "\\;", "\n", "\\n")
+	return r.Replace(s)
+}
+
+// SignICSFeed returns the signature for scheduleID's unauthenticated ICS
+// feed URL, e.g. GET /schedules/{id}/calendar.ics?sig={sig}. Calendar apps
+// poll this URL on their own schedule, so it can't require a JWT - the HMAC
+// is what keeps it from being guessable.
+func (s *ClassService) SignICSFeed(scheduleID uint64) string {
+	mac := hmac.New(sha256.New, []byte(s.icsSigningSecret))
+	fmt.Fprintf(mac, "%d", scheduleID)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyICSFeedSignature checks a signature produced by SignICSFeed.
+func (s *ClassService) VerifyICSFeedSignature(scheduleID uint64, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(s.icsSigningSecret))
+	fmt.Fprintf(mac, "%d", scheduleID)
+	return hmac.Equal(expected, mac.Sum(nil))
+}
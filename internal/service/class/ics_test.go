@@ -0,0 +1,130 @@
+package class
+
+import (
+	"testing"
+	"time"
+	"uni-schedule-backend/internal/domain"
+)
+
+func mustParseDate(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatalf("parsing date %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestExpandClass_WeeklyEveryWeek(t *testing.T) {
+	c := domain.Class{
+		ID:            1,
+		Weekday:       time.Monday,
+		WeekParity:    domain.WeekParityEvery,
+		StartTime:     "09:00",
+		EndTime:       "10:30",
+		SemesterStart: mustParseDate(t, "2026-09-01"), // a Tuesday
+		SemesterEnd:   mustParseDate(t, "2026-09-29"),
+	}
+
+	occurrences := expandClass(c, c.SemesterStart, c.SemesterEnd)
+
+	wantDates := []string{"2026-09-07", "2026-09-14", "2026-09-21", "2026-09-28"}
+	if len(occurrences) != len(wantDates) {
+		t.Fatalf("got %d occurrences, want %d: %+v", len(occurrences), len(wantDates), occurrences)
+	}
+	for i, want := range wantDates {
+		if got := occurrences[i].Start.Format("2006-01-02"); got != want {
+			t.Errorf("occurrence %d: got date %s, want %s", i, got, want)
+		}
+		if occurrences[i].Start.Format("15:04") != "09:00" || occurrences[i].End.Format("15:04") != "10:30" {
+			t.Errorf("occurrence %d: wrong time range %s-%s", i, occurrences[i].Start.Format("15:04"), occurrences[i].End.Format("15:04"))
+		}
+	}
+}
+
+func TestExpandClass_WeekParitySkipsAlternateWeeks(t *testing.T) {
+	c := domain.Class{
+		ID:            2,
+		Weekday:       time.Monday,
+		WeekParity:    domain.WeekParityOdd,
+		StartTime:     "09:00",
+		EndTime:       "10:00",
+		SemesterStart: mustParseDate(t, "2026-09-01"),
+		SemesterEnd:   mustParseDate(t, "2026-09-29"),
+	}
+
+	occurrences := expandClass(c, c.SemesterStart, c.SemesterEnd)
+
+	for _, occ := range occurrences {
+		_, week := occ.Start.ISOWeek()
+		if week%2 != 1 {
+			t.Errorf("occurrence on %s falls in even ISO week %d, want odd", occ.Start.Format("2006-01-02"), week)
+		}
+	}
+	if len(occurrences) == 0 {
+		t.Fatal("expected at least one odd-week occurrence")
+	}
+}
+
+func TestExpandClass_ExceptionDateIsSkipped(t *testing.T) {
+	exception := mustParseDate(t, "2026-09-14")
+	c := domain.Class{
+		ID:             3,
+		Weekday:        time.Monday,
+		WeekParity:     domain.WeekParityEvery,
+		StartTime:      "09:00",
+		EndTime:        "10:00",
+		SemesterStart:  mustParseDate(t, "2026-09-01"),
+		SemesterEnd:    mustParseDate(t, "2026-09-29"),
+		ExceptionDates: []time.Time{exception},
+	}
+
+	occurrences := expandClass(c, c.SemesterStart, c.SemesterEnd)
+
+	for _, occ := range occurrences {
+		if occ.Start.Format("2006-01-02") == "2026-09-14" {
+			t.Fatalf("exception date 2026-09-14 was not skipped")
+		}
+	}
+}
+
+func TestExpandClass_RangeNarrowerThanSemesterIsRespected(t *testing.T) {
+	c := domain.Class{
+		ID:            4,
+		Weekday:       time.Monday,
+		WeekParity:    domain.WeekParityEvery,
+		StartTime:     "09:00",
+		EndTime:       "10:00",
+		SemesterStart: mustParseDate(t, "2026-09-01"),
+		SemesterEnd:   mustParseDate(t, "2026-12-01"),
+	}
+
+	from := mustParseDate(t, "2026-09-01")
+	to := mustParseDate(t, "2026-09-10")
+
+	occurrences := expandClass(c, from, to)
+
+	if len(occurrences) != 1 {
+		t.Fatalf("got %d occurrences, want 1 (only 2026-09-07 falls in range): %+v", len(occurrences), occurrences)
+	}
+}
+
+func TestSignAndVerifyICSFeed(t *testing.T) {
+	s := &ClassService{icsSigningSecret: "test-secret"}
+
+	sig := s.SignICSFeed(42)
+	if !s.VerifyICSFeedSignature(42, sig) {
+		t.Fatal("signature produced by SignICSFeed did not verify")
+	}
+	if s.VerifyICSFeedSignature(43, sig) {
+		t.Fatal("signature for schedule 42 verified against a different schedule ID")
+	}
+	if s.VerifyICSFeedSignature(42, "not-hex") {
+		t.Fatal("non-hex signature should not verify")
+	}
+
+	other := &ClassService{icsSigningSecret: "other-secret"}
+	if other.VerifyICSFeedSignature(42, sig) {
+		t.Fatal("signature verified under a different signing secret")
+	}
+}
@@ -0,0 +1,195 @@
+package schedule
+
+import (
+	"errors"
+	"time"
+	"uni-schedule-backend/internal/apperror"
+	"uni-schedule-backend/internal/authz"
+	"uni-schedule-backend/internal/domain"
+	"uni-schedule-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const inviteTTL = 7 * 24 * time.Hour
+
+// Reauthenticator is satisfied by *auth.AuthService; TransferOwnership uses
+// it to require a fresh password check before handing off a schedule.
+type Reauthenticator interface {
+	VerifyReauth(userID domain.ID, reauthToken string) error
+}
+
+// Authorizer is satisfied by *authz.Authorizer.
+type Authorizer interface {
+	Can(userID domain.ID, scheduleID uint64, perm authz.Permission) (bool, error)
+}
+
+type ScheduleService struct {
+	scheduleRepo     repository.ScheduleRepository
+	collaboratorRepo repository.CollaboratorRepository
+	authz            Authorizer
+	reauth           Reauthenticator
+}
+
+func NewScheduleService(scheduleRepo repository.ScheduleRepository, collaboratorRepo repository.CollaboratorRepository, authz Authorizer, reauth Reauthenticator) *ScheduleService {
+	return &ScheduleService{
+		scheduleRepo:     scheduleRepo,
+		collaboratorRepo: collaboratorRepo,
+		authz:            authz,
+		reauth:           reauth,
+	}
+}
+
+// InviteCollaborator issues a single-use, expiring invitation token for
+// scheduleID at the given role. The returned token is redeemed with
+// AcceptInvite by a user who may not even be registered yet. role can only
+// be viewer or editor - owner is reachable only through the reauth-gated
+// TransferOwnership, never by invite.
+func (s *ScheduleService) InviteCollaborator(inviterID domain.ID, scheduleID uint64, role domain.CollaboratorRole) (uuid.UUID, error) {
+	if role != domain.CollaboratorRoleViewer && role != domain.CollaboratorRoleEditor {
+		return uuid.Nil, apperror.ErrInvalidInviteRole
+	}
+
+	can, err := s.authz.Can(inviterID, scheduleID, authz.PermManageMembers)
+	if err != nil {
+		return uuid.Nil, apperror.NewServiceError("ScheduleService.InviteCollaborator: checking permission", err)
+	}
+	if !can {
+		return uuid.Nil, apperror.ErrDontHavePermission
+	}
+
+	token := uuid.New()
+	err = s.collaboratorRepo.CreateInvite(domain.InvitationCreate{
+		Token:      token,
+		ScheduleID: scheduleID,
+		Role:       role,
+		ExpiresAt:  time.Now().UTC().Add(inviteTTL),
+	})
+	if err != nil {
+		return uuid.Nil, apperror.NewServiceError("ScheduleService.InviteCollaborator: creating invite", err)
+	}
+
+	return token, nil
+}
+
+// AcceptInvite redeems an invitation token for the calling user, adding them
+// as a collaborator at the role the invite was created with.
+func (s *ScheduleService) AcceptInvite(userID domain.ID, token uuid.UUID) error {
+	invite, err := s.collaboratorRepo.GetInviteByToken(token)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return apperror.ErrInvalidInvite
+		}
+		return apperror.NewServiceError("ScheduleService.AcceptInvite: getting invite", err)
+	}
+	if invite.ConsumedAt != nil || time.Now().UTC().After(invite.ExpiresAt) {
+		return apperror.ErrInvalidInvite
+	}
+
+	if err := s.collaboratorRepo.Add(invite.ScheduleID, userID, invite.Role); err != nil {
+		return apperror.NewServiceError("ScheduleService.AcceptInvite: adding collaborator", err)
+	}
+	if err := s.collaboratorRepo.ConsumeInvite(token); err != nil {
+		return apperror.NewServiceError("ScheduleService.AcceptInvite: consuming invite", err)
+	}
+
+	return nil
+}
+
+func (s *ScheduleService) ListCollaborators(userID domain.ID, scheduleID uint64) ([]domain.Collaborator, error) {
+	can, err := s.authz.Can(userID, scheduleID, authz.PermViewClasses)
+	if err != nil {
+		return nil, apperror.NewServiceError("ScheduleService.ListCollaborators: checking permission", err)
+	}
+	if !can {
+		return nil, apperror.ErrDontHavePermission
+	}
+
+	collaborators, err := s.collaboratorRepo.ListForSchedule(scheduleID)
+	if err != nil {
+		return nil, apperror.NewServiceError("ScheduleService.ListCollaborators: listing", err)
+	}
+
+	return collaborators, nil
+}
+
+// RemoveCollaborator revokes targetUserID's access to scheduleID. It's
+// gated by a fresh reauth token, same as TransferOwnership: revoking
+// someone's access is a sensitive, hard-to-reverse action.
+func (s *ScheduleService) RemoveCollaborator(actorID domain.ID, scheduleID uint64, targetUserID domain.ID, reauthToken string) error {
+	can, err := s.authz.Can(actorID, scheduleID, authz.PermManageMembers)
+	if err != nil {
+		return apperror.NewServiceError("ScheduleService.RemoveCollaborator: checking permission", err)
+	}
+	if !can {
+		return apperror.ErrDontHavePermission
+	}
+
+	if err := s.reauth.VerifyReauth(actorID, reauthToken); err != nil {
+		return err
+	}
+
+	if err := s.collaboratorRepo.Remove(scheduleID, targetUserID); err != nil {
+		return apperror.NewServiceError("ScheduleService.RemoveCollaborator:", err)
+	}
+
+	return nil
+}
+
+// TransferOwnership hands schedule ownership to another collaborator,
+// demoting the current owner to editor. It's gated by a fresh reauth token
+// since it permanently gives away control of the schedule. newOwnerID must
+// already be a collaborator - this is validated, and the reauth token
+// checked, before anything is written, since scheduleRepo.UpdateOwner has
+// no transaction wrapping the rest of the writes that follow it.
+func (s *ScheduleService) TransferOwnership(currentOwnerID domain.ID, scheduleID uint64, newOwnerID domain.ID, reauthToken string) error {
+	can, err := s.authz.Can(currentOwnerID, scheduleID, authz.PermManageOwnership)
+	if err != nil {
+		return apperror.NewServiceError("ScheduleService.TransferOwnership: checking permission", err)
+	}
+	if !can {
+		return apperror.ErrDontHavePermission
+	}
+
+	if newOwnerID == currentOwnerID {
+		// Already the owner - nothing to transfer. Without this, the code
+		// below would demote the current owner's own collaborator row to
+		// editor while schedule.UserID keeps pointing at them.
+		return nil
+	}
+
+	if _, err := s.collaboratorRepo.GetByScheduleAndUser(scheduleID, newOwnerID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return apperror.ErrNotCollaborator
+		}
+		return apperror.NewServiceError("ScheduleService.TransferOwnership: checking new owner", err)
+	}
+
+	if err := s.reauth.VerifyReauth(currentOwnerID, reauthToken); err != nil {
+		return err
+	}
+
+	if err := s.scheduleRepo.UpdateOwner(scheduleID, newOwnerID); err != nil {
+		return apperror.NewServiceError("ScheduleService.TransferOwnership: updating owner", err)
+	}
+	if err := s.collaboratorRepo.UpdateRole(scheduleID, newOwnerID, domain.CollaboratorRoleOwner); err != nil {
+		return apperror.NewServiceError("ScheduleService.TransferOwnership: promoting new owner", err)
+	}
+	if err := s.demoteToEditor(scheduleID, currentOwnerID); err != nil {
+		return apperror.NewServiceError("ScheduleService.TransferOwnership: demoting previous owner", err)
+	}
+
+	return nil
+}
+
+// demoteToEditor sets userID's collaborator role to editor, adding a row if
+// they never had one. A solo owner who never invited anyone has no
+// collaborator row at all - owner status was purely schedule.UserID-based -
+// so plain UpdateRole would fail not-found for the common case.
+func (s *ScheduleService) demoteToEditor(scheduleID uint64, userID domain.ID) error {
+	err := s.collaboratorRepo.UpdateRole(scheduleID, userID, domain.CollaboratorRoleEditor)
+	if errors.Is(err, apperror.ErrNotFound) {
+		return s.collaboratorRepo.Add(scheduleID, userID, domain.CollaboratorRoleEditor)
+	}
+	return err
+}
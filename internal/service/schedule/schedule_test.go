@@ -0,0 +1,345 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"uni-schedule-backend/internal/apperror"
+	"uni-schedule-backend/internal/authz"
+	"uni-schedule-backend/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type fakeScheduleRepo struct {
+	schedules map[uint64]domain.Schedule
+}
+
+func (r *fakeScheduleRepo) GetByID(id uint64) (domain.Schedule, error) {
+	schedule, ok := r.schedules[id]
+	if !ok {
+		return domain.Schedule{}, apperror.ErrNotFound
+	}
+	return schedule, nil
+}
+
+func (r *fakeScheduleRepo) UpdateOwner(scheduleID uint64, newOwnerID domain.ID) error {
+	schedule, ok := r.schedules[scheduleID]
+	if !ok {
+		return apperror.ErrNotFound
+	}
+	schedule.UserID = newOwnerID
+	r.schedules[scheduleID] = schedule
+	return nil
+}
+
+// fakeCollaboratorRepo is an in-memory repository.CollaboratorRepository,
+// keyed by (scheduleID, userID), plus a map of pending invites by token.
+type fakeCollaboratorRepo struct {
+	collaborators map[string]domain.Collaborator
+	invites       map[uuid.UUID]domain.Invitation
+}
+
+func newFakeCollaboratorRepo() *fakeCollaboratorRepo {
+	return &fakeCollaboratorRepo{
+		collaborators: map[string]domain.Collaborator{},
+		invites:       map[uuid.UUID]domain.Invitation{},
+	}
+}
+
+func collaboratorKey(scheduleID uint64, userID domain.ID) string {
+	return fmt.Sprintf("%d:%d", scheduleID, userID)
+}
+
+func (r *fakeCollaboratorRepo) Add(scheduleID uint64, userID domain.ID, role domain.CollaboratorRole) error {
+	r.collaborators[collaboratorKey(scheduleID, userID)] = domain.Collaborator{
+		ScheduleID: scheduleID,
+		UserID:     userID,
+		Role:       role,
+		AddedAt:    time.Now().UTC(),
+	}
+	return nil
+}
+
+func (r *fakeCollaboratorRepo) GetByScheduleAndUser(scheduleID uint64, userID domain.ID) (domain.Collaborator, error) {
+	c, ok := r.collaborators[collaboratorKey(scheduleID, userID)]
+	if !ok {
+		return domain.Collaborator{}, apperror.ErrNotFound
+	}
+	return c, nil
+}
+
+func (r *fakeCollaboratorRepo) ListForSchedule(scheduleID uint64) ([]domain.Collaborator, error) {
+	var out []domain.Collaborator
+	for _, c := range r.collaborators {
+		if c.ScheduleID == scheduleID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeCollaboratorRepo) UpdateRole(scheduleID uint64, userID domain.ID, role domain.CollaboratorRole) error {
+	key := collaboratorKey(scheduleID, userID)
+	c, ok := r.collaborators[key]
+	if !ok {
+		return apperror.ErrNotFound
+	}
+	c.Role = role
+	r.collaborators[key] = c
+	return nil
+}
+
+func (r *fakeCollaboratorRepo) Remove(scheduleID uint64, userID domain.ID) error {
+	delete(r.collaborators, collaboratorKey(scheduleID, userID))
+	return nil
+}
+
+func (r *fakeCollaboratorRepo) CreateInvite(invite domain.InvitationCreate) error {
+	r.invites[invite.Token] = domain.Invitation{
+		Token:      invite.Token,
+		ScheduleID: invite.ScheduleID,
+		Role:       invite.Role,
+		ExpiresAt:  invite.ExpiresAt,
+	}
+	return nil
+}
+
+func (r *fakeCollaboratorRepo) GetInviteByToken(token uuid.UUID) (domain.Invitation, error) {
+	invite, ok := r.invites[token]
+	if !ok {
+		return domain.Invitation{}, apperror.ErrNotFound
+	}
+	return invite, nil
+}
+
+func (r *fakeCollaboratorRepo) ConsumeInvite(token uuid.UUID) error {
+	invite, ok := r.invites[token]
+	if !ok {
+		return apperror.ErrNotFound
+	}
+	now := time.Now().UTC()
+	invite.ConsumedAt = &now
+	r.invites[token] = invite
+	return nil
+}
+
+// alwaysAuthorizer lets every call through; it's enough for tests that
+// aren't exercising authz itself (that's authz_test.go's job).
+type alwaysAuthorizer struct{}
+
+func (alwaysAuthorizer) Can(userID domain.ID, scheduleID uint64, perm authz.Permission) (bool, error) {
+	return true, nil
+}
+
+type neverAuthorizer struct{}
+
+func (neverAuthorizer) Can(userID domain.ID, scheduleID uint64, perm authz.Permission) (bool, error) {
+	return false, nil
+}
+
+// fakeReauthenticator records whether VerifyReauth was called and can be
+// made to fail, so tests can tell a guarded operation apart from an
+// unguarded one.
+type fakeReauthenticator struct {
+	err    error
+	called bool
+}
+
+func (f *fakeReauthenticator) VerifyReauth(userID domain.ID, reauthToken string) error {
+	f.called = true
+	return f.err
+}
+
+func newTestScheduleService(scheduleRepo *fakeScheduleRepo, collabRepo *fakeCollaboratorRepo, az Authorizer, reauth *fakeReauthenticator) *ScheduleService {
+	return NewScheduleService(scheduleRepo, collabRepo, az, reauth)
+}
+
+func TestInviteCollaborator_RejectsOwnerRole(t *testing.T) {
+	s := newTestScheduleService(&fakeScheduleRepo{schedules: map[uint64]domain.Schedule{}}, newFakeCollaboratorRepo(), alwaysAuthorizer{}, &fakeReauthenticator{})
+
+	_, err := s.InviteCollaborator(1, 10, domain.CollaboratorRoleOwner)
+	if !errors.Is(err, apperror.ErrInvalidInviteRole) {
+		t.Fatalf("got %v, want ErrInvalidInviteRole", err)
+	}
+}
+
+func TestInviteCollaborator_ViewerAndEditorAllowed(t *testing.T) {
+	for _, role := range []domain.CollaboratorRole{domain.CollaboratorRoleViewer, domain.CollaboratorRoleEditor} {
+		collabRepo := newFakeCollaboratorRepo()
+		s := newTestScheduleService(&fakeScheduleRepo{schedules: map[uint64]domain.Schedule{}}, collabRepo, alwaysAuthorizer{}, &fakeReauthenticator{})
+
+		token, err := s.InviteCollaborator(1, 10, role)
+		if err != nil {
+			t.Fatalf("role %v: unexpected error %v", role, err)
+		}
+		invite, ok := collabRepo.invites[token]
+		if !ok || invite.Role != role {
+			t.Errorf("role %v: invite not stored with the requested role: %+v", role, invite)
+		}
+	}
+}
+
+func TestInviteCollaborator_RequiresManageMembersPermission(t *testing.T) {
+	s := newTestScheduleService(&fakeScheduleRepo{schedules: map[uint64]domain.Schedule{}}, newFakeCollaboratorRepo(), neverAuthorizer{}, &fakeReauthenticator{})
+
+	_, err := s.InviteCollaborator(1, 10, domain.CollaboratorRoleEditor)
+	if !errors.Is(err, apperror.ErrDontHavePermission) {
+		t.Fatalf("got %v, want ErrDontHavePermission", err)
+	}
+}
+
+func TestAcceptInvite_ExpiredInviteRejected(t *testing.T) {
+	collabRepo := newFakeCollaboratorRepo()
+	token := uuid.New()
+	collabRepo.invites[token] = domain.Invitation{
+		Token:      token,
+		ScheduleID: 10,
+		Role:       domain.CollaboratorRoleEditor,
+		ExpiresAt:  time.Now().UTC().Add(-time.Hour),
+	}
+	s := newTestScheduleService(&fakeScheduleRepo{schedules: map[uint64]domain.Schedule{}}, collabRepo, alwaysAuthorizer{}, &fakeReauthenticator{})
+
+	err := s.AcceptInvite(2, token)
+	if !errors.Is(err, apperror.ErrInvalidInvite) {
+		t.Fatalf("got %v, want ErrInvalidInvite for an expired invite", err)
+	}
+}
+
+func TestAcceptInvite_AlreadyConsumedRejected(t *testing.T) {
+	collabRepo := newFakeCollaboratorRepo()
+	token := uuid.New()
+	consumedAt := time.Now().UTC().Add(-time.Minute)
+	collabRepo.invites[token] = domain.Invitation{
+		Token:      token,
+		ScheduleID: 10,
+		Role:       domain.CollaboratorRoleEditor,
+		ExpiresAt:  time.Now().UTC().Add(time.Hour),
+		ConsumedAt: &consumedAt,
+	}
+	s := newTestScheduleService(&fakeScheduleRepo{schedules: map[uint64]domain.Schedule{}}, collabRepo, alwaysAuthorizer{}, &fakeReauthenticator{})
+
+	err := s.AcceptInvite(2, token)
+	if !errors.Is(err, apperror.ErrInvalidInvite) {
+		t.Fatalf("got %v, want ErrInvalidInvite for an already-consumed invite", err)
+	}
+}
+
+func TestAcceptInvite_Success(t *testing.T) {
+	collabRepo := newFakeCollaboratorRepo()
+	token := uuid.New()
+	collabRepo.invites[token] = domain.Invitation{
+		Token:      token,
+		ScheduleID: 10,
+		Role:       domain.CollaboratorRoleViewer,
+		ExpiresAt:  time.Now().UTC().Add(time.Hour),
+	}
+	s := newTestScheduleService(&fakeScheduleRepo{schedules: map[uint64]domain.Schedule{}}, collabRepo, alwaysAuthorizer{}, &fakeReauthenticator{})
+
+	if err := s.AcceptInvite(2, token); err != nil {
+		t.Fatalf("AcceptInvite: %v", err)
+	}
+
+	c, err := collabRepo.GetByScheduleAndUser(10, 2)
+	if err != nil {
+		t.Fatalf("invitee was not added as a collaborator: %v", err)
+	}
+	if c.Role != domain.CollaboratorRoleViewer {
+		t.Errorf("got role %v, want viewer", c.Role)
+	}
+	if collabRepo.invites[token].ConsumedAt == nil {
+		t.Error("invite should be marked consumed after acceptance")
+	}
+}
+
+func TestRemoveCollaborator_RequiresReauth(t *testing.T) {
+	collabRepo := newFakeCollaboratorRepo()
+	_ = collabRepo.Add(10, 2, domain.CollaboratorRoleEditor)
+	reauth := &fakeReauthenticator{err: apperror.ErrInvalidReauthToken}
+	s := newTestScheduleService(&fakeScheduleRepo{schedules: map[uint64]domain.Schedule{}}, collabRepo, alwaysAuthorizer{}, reauth)
+
+	err := s.RemoveCollaborator(1, 10, 2, "bad-token")
+	if !errors.Is(err, apperror.ErrInvalidReauthToken) {
+		t.Fatalf("got %v, want ErrInvalidReauthToken", err)
+	}
+	if !reauth.called {
+		t.Error("RemoveCollaborator did not call VerifyReauth")
+	}
+	if _, err := collabRepo.GetByScheduleAndUser(10, 2); err != nil {
+		t.Error("collaborator should not have been removed when reauth fails")
+	}
+}
+
+func TestRemoveCollaborator_Success(t *testing.T) {
+	collabRepo := newFakeCollaboratorRepo()
+	_ = collabRepo.Add(10, 2, domain.CollaboratorRoleEditor)
+	s := newTestScheduleService(&fakeScheduleRepo{schedules: map[uint64]domain.Schedule{}}, collabRepo, alwaysAuthorizer{}, &fakeReauthenticator{})
+
+	if err := s.RemoveCollaborator(1, 10, 2, "good-token"); err != nil {
+		t.Fatalf("RemoveCollaborator: %v", err)
+	}
+	if _, err := collabRepo.GetByScheduleAndUser(10, 2); !errors.Is(err, apperror.ErrNotFound) {
+		t.Error("collaborator should have been removed")
+	}
+}
+
+func TestDemoteToEditor_AddsRowWhenOwnerHasNone(t *testing.T) {
+	collabRepo := newFakeCollaboratorRepo()
+	s := newTestScheduleService(&fakeScheduleRepo{schedules: map[uint64]domain.Schedule{}}, collabRepo, alwaysAuthorizer{}, &fakeReauthenticator{})
+
+	// A solo owner has no collaborator row at all - owner status was purely
+	// schedule.UserID based - so this must fall back to Add rather than
+	// failing not-found on UpdateRole.
+	if err := s.demoteToEditor(10, 1); err != nil {
+		t.Fatalf("demoteToEditor: %v", err)
+	}
+
+	c, err := collabRepo.GetByScheduleAndUser(10, 1)
+	if err != nil {
+		t.Fatalf("expected a new collaborator row for the demoted owner: %v", err)
+	}
+	if c.Role != domain.CollaboratorRoleEditor {
+		t.Errorf("got role %v, want editor", c.Role)
+	}
+}
+
+func TestTransferOwnership_SelfTransferIsNoOp(t *testing.T) {
+	scheduleRepo := &fakeScheduleRepo{schedules: map[uint64]domain.Schedule{10: {ID: 10, UserID: 1}}}
+	collabRepo := newFakeCollaboratorRepo()
+	_ = collabRepo.Add(10, 1, domain.CollaboratorRoleOwner)
+	reauth := &fakeReauthenticator{}
+	s := newTestScheduleService(scheduleRepo, collabRepo, alwaysAuthorizer{}, reauth)
+
+	if err := s.TransferOwnership(1, 10, 1, "token"); err != nil {
+		t.Fatalf("TransferOwnership: %v", err)
+	}
+
+	if reauth.called {
+		t.Error("self-transfer should short-circuit before touching reauth")
+	}
+	c, err := collabRepo.GetByScheduleAndUser(10, 1)
+	if err != nil {
+		t.Fatalf("owner's collaborator row should be untouched: %v", err)
+	}
+	if c.Role != domain.CollaboratorRoleOwner {
+		t.Errorf("self-transfer demoted the owner's own row to %v, want it to stay owner", c.Role)
+	}
+	if scheduleRepo.schedules[10].UserID != 1 {
+		t.Error("self-transfer should not change schedule.UserID")
+	}
+}
+
+func TestTransferOwnership_RejectsNonCollaborator(t *testing.T) {
+	scheduleRepo := &fakeScheduleRepo{schedules: map[uint64]domain.Schedule{10: {ID: 10, UserID: 1}}}
+	collabRepo := newFakeCollaboratorRepo()
+	s := newTestScheduleService(scheduleRepo, collabRepo, alwaysAuthorizer{}, &fakeReauthenticator{})
+
+	err := s.TransferOwnership(1, 10, 2, "token")
+	if !errors.Is(err, apperror.ErrNotCollaborator) {
+		t.Fatalf("got %v, want ErrNotCollaborator", err)
+	}
+	if scheduleRepo.schedules[10].UserID != 1 {
+		t.Error("ownership must not change when the new owner isn't a collaborator")
+	}
+}
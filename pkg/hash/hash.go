@@ -0,0 +1,15 @@
+package hash
+
+import "golang.org/x/crypto/bcrypt"
+
+func HashPassword(password, salt string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password+salt), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func VerifyPassword(password, passwordHash, salt string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password+salt)) == nil
+}